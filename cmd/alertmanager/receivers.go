@@ -0,0 +1,121 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/notify/webex"
+	_ "github.com/prometheus/alertmanager/notify/wecomrobot" // registers the "wecomrobot" notify.Plugin
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+)
+
+var loadNotifierPluginsOnce sync.Once
+
+// loadNotifierPlugins populates the notify.Plugin registry from global, the
+// once-per-process counterpart of a real main() calling it eagerly at
+// startup before any receiver is built. It is invoked lazily, the first
+// time buildReceiverIntegrations runs, since this package has no main of
+// its own to call it from directly.
+func loadNotifierPlugins(global *config.GlobalConfig, logger log.Logger) error {
+	var err error
+	loadNotifierPluginsOnce.Do(func() {
+		if global == nil {
+			return
+		}
+		if global.PluginDir != "" {
+			if e := notify.LoadPlugins(global.PluginDir, logger); e != nil {
+				err = fmt.Errorf("loading plugin_dir %q: %w", global.PluginDir, e)
+				return
+			}
+		}
+		for name, socketPath := range global.PluginRPCSockets {
+			if e := notify.RegisterRPCPlugin(context.Background(), name, socketPath); e != nil {
+				err = fmt.Errorf("registering plugin_rpc_socket %q: %w", name, e)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// buildReceiverIntegrations builds one notify.Integration per configured
+// notifier on nc, in the same add-and-collect-errors style used for every
+// other receiver type.
+func buildReceiverIntegrations(nc *config.Receiver, global *config.GlobalConfig, tmpl *template.Template, logger log.Logger) ([]notify.Integration, error) {
+	var (
+		errs         types.MultiError
+		integrations []notify.Integration
+		add          = func(name string, i int, rs notify.ResolvedSender, f func(l log.Logger) (notify.Notifier, error)) {
+			n, err := f(log.With(logger, "integration", name))
+			if err != nil {
+				errs.Add(err)
+				return
+			}
+			integrations = append(integrations, notify.NewIntegration(n, rs, name, i))
+		}
+	)
+
+	if err := loadNotifierPlugins(global, logger); err != nil {
+		errs.Add(err)
+	}
+
+	for i, c := range nc.WeComRobotConfigs {
+		add("wecomrobot", i, c, func(l log.Logger) (notify.Notifier, error) {
+			p, ok := notify.Lookup("wecomrobot")
+			if !ok {
+				return nil, fmt.Errorf("wecomrobot_configs: no \"wecomrobot\" notify.Plugin registered")
+			}
+			raw, err := json.Marshal(c)
+			if err != nil {
+				return nil, err
+			}
+			return p.New(raw, tmpl, l)
+		})
+	}
+
+	for i, c := range nc.WebexConfigs {
+		add("webex", i, c, func(l log.Logger) (notify.Notifier, error) {
+			return webex.New(c, tmpl, l)
+		})
+	}
+
+	pluginNotifiers, err := notify.BuildPluginIntegrations(nc.CustomConfigs, tmpl, logger)
+	if err != nil {
+		errs.Add(err)
+	}
+	for i, n := range pluginNotifiers {
+		integrations = append(integrations, notify.NewIntegration(n, pluginResolvedSender(true), "plugin", i))
+	}
+
+	if errs.Len() > 0 {
+		return nil, &errs
+	}
+	return integrations, nil
+}
+
+// pluginResolvedSender always sends resolved notifications for plugin-based
+// receivers: a CustomConfigs entry is opaque json.RawMessage by the time it
+// reaches BuildPluginIntegrations, so there's no send_resolved field here to
+// read the way NotifierConfig.SendResolved reads it for the built-in types.
+type pluginResolvedSender bool
+
+func (s pluginResolvedSender) SendResolved() bool { return bool(s) }
@@ -0,0 +1,119 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GlobalConfig holds settings that apply across all receivers rather than
+// to one in particular.
+type GlobalConfig struct {
+	// PluginDir, if set, is scanned once at startup for *.so files to load
+	// via notify.LoadPlugins.
+	PluginDir string `yaml:"plugin_dir,omitempty" json:"plugin_dir,omitempty"`
+
+	// PluginRPCSockets registers an out-of-process plugin for each
+	// name -> Unix socket path pair via notify.RegisterRPCPlugin, for
+	// builds where the native `plugin` package loaded by PluginDir isn't
+	// available.
+	PluginRPCSockets map[string]string `yaml:"plugin_rpc_sockets,omitempty" json:"plugin_rpc_sockets,omitempty"`
+}
+
+// Receiver configures one or more notification integrations that a route
+// can forward alerts to.
+type Receiver struct {
+	Name string `yaml:"name" json:"name"`
+
+	WeComRobotConfigs []*WeComRobotConfig `yaml:"wecomrobot_configs,omitempty" json:"wecomrobot_configs,omitempty"`
+	WebexConfigs      []*WebexConfig      `yaml:"webex_configs,omitempty" json:"webex_configs,omitempty"`
+
+	// CustomConfigs holds every YAML key under this receiver that isn't one
+	// of the built-in *_configs fields above, keyed by that key name and
+	// dispatched to the notify.Plugin registered under it.
+	CustomConfigs map[string][]json.RawMessage `yaml:"-" json:"-"`
+}
+
+// knownReceiverKeys are the YAML keys Receiver itself understands; every
+// other key found alongside them is assumed to belong to a notify.Plugin
+// and captured into CustomConfigs instead.
+var knownReceiverKeys = map[string]bool{
+	"name":               true,
+	"wecomrobot_configs": true,
+	"webex_configs":      true,
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *Receiver) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Receiver
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if c.Name == "" {
+		return fmt.Errorf("missing name in receiver")
+	}
+
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	for key, v := range raw {
+		if knownReceiverKeys[key] {
+			continue
+		}
+
+		entries, ok := cleanYAMLValue(v).([]interface{})
+		if !ok {
+			entries = []interface{}{v}
+		}
+
+		for _, entry := range entries {
+			b, err := json.Marshal(cleanYAMLValue(entry))
+			if err != nil {
+				return fmt.Errorf("marshaling custom_configs entry %q: %w", key, err)
+			}
+			if c.CustomConfigs == nil {
+				c.CustomConfigs = map[string][]json.RawMessage{}
+			}
+			c.CustomConfigs[key] = append(c.CustomConfigs[key], b)
+		}
+	}
+
+	return nil
+}
+
+// cleanYAMLValue recursively converts the map[interface{}]interface{}
+// nodes gopkg.in/yaml.v2 produces into map[string]interface{} so the
+// result can be passed to encoding/json.
+func cleanYAMLValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = cleanYAMLValue(val)
+		}
+		return m
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = cleanYAMLValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
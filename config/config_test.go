@@ -0,0 +1,88 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestReceiverUnmarshalYAMLMissingName(t *testing.T) {
+	var r Receiver
+	err := yaml.Unmarshal([]byte("wecomrobot_configs: []\n"), &r)
+	require.Error(t, err)
+}
+
+func TestReceiverUnmarshalYAMLIgnoresKnownKeys(t *testing.T) {
+	var r Receiver
+	err := yaml.Unmarshal([]byte("name: team-a\nwecomrobot_configs: []\nwebex_configs: []\n"), &r)
+	require.NoError(t, err)
+	require.Empty(t, r.CustomConfigs)
+}
+
+func TestReceiverUnmarshalYAMLCapturesCustomConfigsSingularValue(t *testing.T) {
+	var r Receiver
+	err := yaml.Unmarshal([]byte(`
+name: team-a
+pagerduty_custom:
+  integration_key: abc123
+`), &r)
+	require.NoError(t, err)
+
+	require.Len(t, r.CustomConfigs["pagerduty_custom"], 1)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(r.CustomConfigs["pagerduty_custom"][0], &got))
+	require.Equal(t, "abc123", got["integration_key"])
+}
+
+func TestReceiverUnmarshalYAMLCapturesCustomConfigsListValue(t *testing.T) {
+	var r Receiver
+	err := yaml.Unmarshal([]byte(`
+name: team-a
+pagerduty_custom:
+  - integration_key: abc123
+  - integration_key: def456
+`), &r)
+	require.NoError(t, err)
+
+	require.Len(t, r.CustomConfigs["pagerduty_custom"], 2)
+
+	var first, second map[string]interface{}
+	require.NoError(t, json.Unmarshal(r.CustomConfigs["pagerduty_custom"][0], &first))
+	require.NoError(t, json.Unmarshal(r.CustomConfigs["pagerduty_custom"][1], &second))
+	require.Equal(t, "abc123", first["integration_key"])
+	require.Equal(t, "def456", second["integration_key"])
+}
+
+func TestReceiverUnmarshalYAMLCapturesNestedMaps(t *testing.T) {
+	var r Receiver
+	err := yaml.Unmarshal([]byte(`
+name: team-a
+pagerduty_custom:
+  integration_key: abc123
+  details:
+    severity: critical
+`), &r)
+	require.NoError(t, err)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(r.CustomConfigs["pagerduty_custom"][0], &got))
+	details, ok := got["details"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "critical", details["severity"])
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+
+	commoncfg "github.com/prometheus/common/config"
+)
+
+// NotifierConfig contains base options common across all notifier
+// configurations.
+type NotifierConfig struct {
+	VSendResolved bool `yaml:"send_resolved" json:"send_resolved"`
+}
+
+// SendResolved returns whether resolved notifications should be sent.
+func (nc *NotifierConfig) SendResolved() bool {
+	return nc.VSendResolved
+}
+
+// WeComRobotArticle is a single article rendered in a WeCom "news" message.
+type WeComRobotArticle struct {
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	URL         string `yaml:"url" json:"url"`
+	PicURL      string `yaml:"pic_url,omitempty" json:"pic_url,omitempty"`
+}
+
+// WeComRobotCardTemplate holds the fields rendered into a WeCom
+// "template_card" message.
+type WeComRobotCardTemplate struct {
+	MainTitle   string `yaml:"main_title" json:"main_title"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	URL         string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// WeComRobotConfig configures notifications sent to a WeChat Work (WeCom)
+// group robot webhook.
+type WeComRobotConfig struct {
+	NotifierConfig `yaml:",inline" json:",inline"`
+
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+	WebhookURL URL                         `yaml:"webhook_url" json:"webhook_url"`
+
+	MsgType        string `yaml:"msg_type,omitempty" json:"msg_type,omitempty"`
+	Message        string `yaml:"message,omitempty" json:"message,omitempty"`
+	MaxMessageSize int    `yaml:"max_message_size,omitempty" json:"max_message_size,omitempty"`
+
+	// Populated when MsgType is "news".
+	Articles []WeComRobotArticle `yaml:"articles,omitempty" json:"articles,omitempty"`
+
+	// Populated when MsgType is "image".
+	ImageBase64 string `yaml:"image_base64,omitempty" json:"image_base64,omitempty"`
+	ImageMD5    string `yaml:"image_md5,omitempty" json:"image_md5,omitempty"`
+
+	// Populated when MsgType is "template_card".
+	CardTemplate *WeComRobotCardTemplate `yaml:"card_template,omitempty" json:"card_template,omitempty"`
+
+	// MentionedList and MentionedMobileList are templated from alert labels
+	// and attached to "text"/"markdown" messages to @mention responders.
+	MentionedList       []string `yaml:"mentioned_list,omitempty" json:"mentioned_list,omitempty"`
+	MentionedMobileList []string `yaml:"mentioned_mobile_list,omitempty" json:"mentioned_mobile_list,omitempty"`
+
+	// RateLimit and RateLimitBurst bound how many messages, and how large a
+	// burst, are sent to this webhook per 60s. Both default to 20, matching
+	// WeCom's own per-bot quota.
+	RateLimit      int `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	RateLimitBurst int `yaml:"rate_limit_burst,omitempty" json:"rate_limit_burst,omitempty"`
+
+	// AttachmentURL is templated from alert annotations and, when set,
+	// chains a second "image" or "file" message after the primary one.
+	// AttachmentType selects which; it defaults to "image".
+	AttachmentURL  string `yaml:"attachment_url,omitempty" json:"attachment_url,omitempty"`
+	AttachmentType string `yaml:"attachment_type,omitempty" json:"attachment_type,omitempty"`
+}
+
+// WebexConfig configures notifications sent to a Cisco Webex Teams room.
+type WebexConfig struct {
+	NotifierConfig `yaml:",inline" json:",inline"`
+
+	HTTPConfig *commoncfg.HTTPClientConfig `yaml:"http_config,omitempty" json:"http_config,omitempty"`
+	APIURL     string                      `yaml:"api_url,omitempty" json:"api_url,omitempty"`
+	BotToken   Secret                      `yaml:"bot_token" json:"-"`
+	RoomID     string                      `yaml:"room_id" json:"room_id"`
+	Message    string                      `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+const defaultWebexAPIURL = "https://webexapis.com/v1/messages"
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *WebexConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain WebexConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if c.RoomID == "" {
+		return fmt.Errorf("missing room_id on webex_config")
+	}
+	if c.BotToken == "" {
+		return fmt.Errorf("missing bot_token on webex_config")
+	}
+	if c.APIURL == "" {
+		c.APIURL = defaultWebexAPIURL
+	}
+
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (c *WeComRobotConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain WeComRobotConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if c.MsgType == "" {
+		c.MsgType = "text"
+	}
+
+	return c.Validate()
+}
+
+// Validate checks that WebhookURL is set and that the fields required by
+// MsgType are present. New() also calls it directly for configs assembled
+// outside of YAML unmarshaling (e.g. from a notify.Plugin's raw JSON), where
+// UnmarshalYAML never runs.
+func (c *WeComRobotConfig) Validate() error {
+	if c.WebhookURL.URL == nil {
+		return fmt.Errorf("missing webhook_url on wecomrobot_config")
+	}
+
+	switch c.MsgType {
+	case "", "text", "markdown":
+		// An empty MsgType is sent as "text". Message may be empty; TmplText
+		// will simply render an empty body.
+	case "news":
+		if len(c.Articles) == 0 {
+			return fmt.Errorf("wecomrobot_config with msg_type \"news\" requires at least one article")
+		}
+	case "image":
+		if c.ImageBase64 == "" || c.ImageMD5 == "" {
+			return fmt.Errorf("wecomrobot_config with msg_type \"image\" requires image_base64 and image_md5")
+		}
+	case "template_card":
+		if c.CardTemplate == nil {
+			return fmt.Errorf("wecomrobot_config with msg_type \"template_card\" requires a card_template block")
+		}
+	default:
+		return fmt.Errorf("unsupported wecomrobot_config msg_type %q", c.MsgType)
+	}
+
+	switch c.AttachmentType {
+	case "", "image", "file":
+	default:
+		return fmt.Errorf("unsupported wecomrobot_config attachment_type %q", c.AttachmentType)
+	}
+
+	return nil
+}
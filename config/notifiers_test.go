@@ -0,0 +1,158 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func validWeComRobotConfig() *WeComRobotConfig {
+	u, _ := url.Parse("https://example.com/webhook?key=test")
+	return &WeComRobotConfig{WebhookURL: URL{URL: u}, MsgType: "text"}
+}
+
+func TestWeComRobotConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		mutate  func(c *WeComRobotConfig)
+		wantErr bool
+	}{
+		{"valid text", func(c *WeComRobotConfig) {}, false},
+		{"missing webhook_url", func(c *WeComRobotConfig) { c.WebhookURL = URL{} }, true},
+		{"news without articles", func(c *WeComRobotConfig) { c.MsgType = "news" }, true},
+		{"news with article", func(c *WeComRobotConfig) {
+			c.MsgType = "news"
+			c.Articles = []WeComRobotArticle{{Title: "t", URL: "https://example.com"}}
+		}, false},
+		{"image missing image_md5", func(c *WeComRobotConfig) {
+			c.MsgType = "image"
+			c.ImageBase64 = "YmFzZTY0"
+		}, true},
+		{"image with both fields", func(c *WeComRobotConfig) {
+			c.MsgType = "image"
+			c.ImageBase64 = "YmFzZTY0"
+			c.ImageMD5 = "deadbeef"
+		}, false},
+		{"template_card without card_template", func(c *WeComRobotConfig) { c.MsgType = "template_card" }, true},
+		{"template_card with card_template", func(c *WeComRobotConfig) {
+			c.MsgType = "template_card"
+			c.CardTemplate = &WeComRobotCardTemplate{MainTitle: "t"}
+		}, false},
+		{"unsupported msg_type", func(c *WeComRobotConfig) { c.MsgType = "carrier_pigeon" }, true},
+		{"unsupported attachment_type", func(c *WeComRobotConfig) { c.AttachmentType = "fax" }, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := validWeComRobotConfig()
+			tc.mutate(c)
+
+			err := c.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWeComRobotConfigUnmarshalYAML(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      string
+		wantErr bool
+		check   func(t *testing.T, c WeComRobotConfig)
+	}{
+		{
+			name: "valid webhook_url",
+			in:   "webhook_url: https://example.com/webhook?key=test\nmsg_type: text\n",
+			check: func(t *testing.T, c WeComRobotConfig) {
+				require.Equal(t, "https://example.com/webhook?key=test", c.WebhookURL.String())
+			},
+		},
+		{
+			name:    "missing webhook_url",
+			in:      "msg_type: text\n",
+			wantErr: true,
+		},
+		{
+			name:    "webhook_url without host",
+			in:      "webhook_url: /webhook?key=test\nmsg_type: text\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c WeComRobotConfig
+			err := yaml.Unmarshal([]byte(tc.in), &c)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.check != nil {
+				tc.check(t, c)
+			}
+		})
+	}
+}
+
+func TestWebexConfigUnmarshalYAML(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      string
+		wantErr bool
+		check   func(t *testing.T, c WebexConfig)
+	}{
+		{
+			name:    "missing room_id",
+			in:      "bot_token: token\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing bot_token",
+			in:      "room_id: room-1\n",
+			wantErr: true,
+		},
+		{
+			name: "api_url defaults when unset",
+			in:   "room_id: room-1\nbot_token: token\n",
+			check: func(t *testing.T, c WebexConfig) {
+				require.Equal(t, defaultWebexAPIURL, c.APIURL)
+			},
+		},
+		{
+			name: "api_url preserved when set",
+			in:   "room_id: room-1\nbot_token: token\napi_url: https://example.com/webex\n",
+			check: func(t *testing.T, c WebexConfig) {
+				require.Equal(t, "https://example.com/webex", c.APIURL)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var c WebexConfig
+			err := yaml.Unmarshal([]byte(tc.in), &c)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.check != nil {
+				tc.check(t, c)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// secretToken is substituted for a Secret's actual value whenever it is
+// marshaled back out, so that printing a loaded config never leaks it.
+const secretToken = "<secret>"
+
+// Secret is a string that must not be revealed on marshal.
+type Secret string
+
+// MarshalYAML implements the yaml.Marshaler interface for Secret.
+func (s Secret) MarshalYAML() (interface{}, error) {
+	if s != "" {
+		return secretToken, nil
+	}
+	return nil, nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for Secret.
+func (s *Secret) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain string
+	return unmarshal((*plain)(s))
+}
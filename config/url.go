@@ -0,0 +1,86 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// URL wraps *url.URL so it can be read from and written back out as a
+// plain string, the way it appears in YAML and JSON. *url.URL itself has
+// no UnmarshalYAML/UnmarshalJSON, so without this wrapper yaml.v2 tries to
+// decode the scalar into url.URL's fields directly and fails.
+type URL struct {
+	*url.URL
+}
+
+// parseURL parses s into a URL, rejecting anything without a host so a
+// typo'd or relative value is caught at load time rather than at the
+// first failed request.
+func parseURL(s string) (URL, error) {
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	if parsed.Host == "" {
+		return URL{}, fmt.Errorf("host is missing in URL %q", s)
+	}
+	return URL{parsed}, nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface for URL.
+func (u URL) MarshalYAML() (interface{}, error) {
+	if u.URL != nil {
+		return u.String(), nil
+	}
+	return nil, nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface for URL.
+func (u *URL) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := parseURL(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface for URL.
+func (u URL) MarshalJSON() ([]byte, error) {
+	if u.URL != nil {
+		return json.Marshal(u.String())
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for URL.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := parseURL(s)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/notify/pluginrpc"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// Plugin lets an out-of-tree notifier register itself without forking
+// Alertmanager. A plugin built as a Go `.so` exports it under the symbol
+// name "Plugin", which LoadPlugins registers for every `.so` under the
+// configured plugin_dir at startup; an in-tree plugin calls Register from
+// an init() func instead.
+type Plugin interface {
+	// Name identifies the plugin. It is matched against the key under a
+	// receiver's CustomConfigs map in the configuration file.
+	Name() string
+
+	// New builds a Notifier from one CustomConfigs entry's raw config.
+	New(raw json.RawMessage, tmpl *template.Template, logger log.Logger) (Notifier, error)
+}
+
+var (
+	pluginsMu sync.Mutex
+	plugins   = map[string]Plugin{}
+)
+
+// Register makes p available to receivers whose CustomConfigs key matches
+// p.Name(). Plugins call this from their init().
+func Register(p Plugin) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	plugins[p.Name()] = p
+}
+
+// Lookup returns the plugin registered under name, if any.
+func Lookup(name string) (Plugin, bool) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	p, ok := plugins[name]
+	return p, ok
+}
+
+// LoadPlugins opens every *.so file in dir via the Go plugin package and
+// registers the Plugin each exports under the symbol name "Plugin". It is
+// called once at startup with the configured plugin_dir, before any
+// CustomConfigs receiver is built.
+func LoadPlugins(dir string, logger log.Logger) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading plugin_dir %q: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".so" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		p, err := plugin.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening plugin %q: %w", path, err)
+		}
+
+		sym, err := p.Lookup("Plugin")
+		if err != nil {
+			return fmt.Errorf("plugin %q does not export a Plugin symbol: %w", path, err)
+		}
+
+		impl, ok := sym.(Plugin)
+		if !ok {
+			return fmt.Errorf("plugin %q's Plugin symbol does not implement notify.Plugin", path)
+		}
+
+		Register(impl)
+		level.Info(logger).Log("msg", "loaded notifier plugin", "name", impl.Name(), "path", path)
+	}
+
+	return nil
+}
+
+// BuildPluginIntegrations builds one Notifier per entry of customConfigs,
+// dispatching each key to its registered Plugin - the "matching registered
+// plugin" a receiver's CustomConfigs map resolves to. It uses the same
+// add-and-collect-errors pattern as the built-in receiver types.
+func BuildPluginIntegrations(customConfigs map[string][]json.RawMessage, tmpl *template.Template, logger log.Logger) ([]Notifier, error) {
+	var (
+		errs      types.MultiError
+		notifiers []Notifier
+	)
+
+	for name, rawConfigs := range customConfigs {
+		p, ok := Lookup(name)
+		if !ok {
+			errs.Add(fmt.Errorf("no notifier plugin registered for custom_configs key %q", name))
+			continue
+		}
+
+		for _, raw := range rawConfigs {
+			n, err := p.New(raw, tmpl, log.With(logger, "plugin", name))
+			if err != nil {
+				errs.Add(fmt.Errorf("building plugin %q notifier: %w", name, err))
+				continue
+			}
+			notifiers = append(notifiers, n)
+		}
+	}
+
+	if errs.Len() > 0 {
+		return notifiers, &errs
+	}
+	return notifiers, nil
+}
+
+// RegisterRPCPlugin dials a pluginrpc server listening on socketPath and
+// registers it under name, the gRPC-over-Unix-socket counterpart to
+// loading a .so via LoadPlugins - for builds where the native `plugin`
+// package isn't available.
+func RegisterRPCPlugin(ctx context.Context, name, socketPath string) error {
+	client, err := pluginrpc.DialPlugin(ctx, socketPath)
+	if err != nil {
+		return fmt.Errorf("dialing plugin %q at %q: %w", name, socketPath, err)
+	}
+
+	Register(&rpcPlugin{name: name, client: client})
+	return nil
+}
+
+// rpcPlugin adapts a pluginrpc.Client to the Plugin interface, so a plugin
+// process reached over a Unix socket is registered and dispatched to
+// exactly like an in-process .so plugin.
+type rpcPlugin struct {
+	name   string
+	client *pluginrpc.Client
+}
+
+func (p *rpcPlugin) Name() string { return p.name }
+
+func (p *rpcPlugin) New(raw json.RawMessage, tmpl *template.Template, logger log.Logger) (Notifier, error) {
+	return &rpcNotifier{client: p.client, config: raw, tmpl: tmpl, logger: logger}, nil
+}
+
+// rpcNotifier implements Notifier by forwarding each call over the RPC
+// client to the out-of-process plugin.
+type rpcNotifier struct {
+	client *pluginrpc.Client
+	config json.RawMessage
+	tmpl   *template.Template
+	logger log.Logger
+}
+
+func (n *rpcNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	data := GetTemplateData(ctx, n.tmpl, alerts, n.logger)
+
+	alertsJSON, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := n.client.Notify(ctx, &pluginrpc.NotifyRequest{Config: n.config, Alerts: alertsJSON})
+	if err != nil {
+		return true, err
+	}
+	if resp.Error != "" {
+		return resp.Retry, errors.New(resp.Error)
+	}
+
+	return resp.Retry, nil
+}
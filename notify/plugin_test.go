@@ -0,0 +1,96 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePlugin struct {
+	name string
+	err  error
+}
+
+func (p *fakePlugin) Name() string { return p.name }
+
+func (p *fakePlugin) New(raw json.RawMessage, tmpl *template.Template, logger log.Logger) (Notifier, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return fakeNotifier{}, nil
+}
+
+type fakeNotifier struct{}
+
+func (fakeNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	return false, nil
+}
+
+func TestRegisterLookup(t *testing.T) {
+	p := &fakePlugin{name: "test-plugin-register-lookup"}
+	Register(p)
+
+	got, ok := Lookup(p.name)
+	require.True(t, ok)
+	require.Same(t, p, got)
+
+	_, ok = Lookup("plugin-that-was-never-registered")
+	require.False(t, ok)
+}
+
+func TestBuildPluginIntegrations(t *testing.T) {
+	name := "test-build-plugin-integrations"
+	Register(&fakePlugin{name: name})
+
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+
+	notifiers, err := BuildPluginIntegrations(map[string][]json.RawMessage{
+		name: {json.RawMessage(`{}`), json.RawMessage(`{}`)},
+	}, tmpl, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, notifiers, 2)
+}
+
+func TestBuildPluginIntegrationsUnknownPlugin(t *testing.T) {
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+
+	_, err = BuildPluginIntegrations(map[string][]json.RawMessage{
+		"plugin-that-was-never-registered": {json.RawMessage(`{}`)},
+	}, tmpl, log.NewNopLogger())
+	require.Error(t, err)
+}
+
+func TestBuildPluginIntegrationsPluginConstructorError(t *testing.T) {
+	name := "test-build-plugin-integrations-error"
+	Register(&fakePlugin{name: name, err: errors.New("boom")})
+
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+
+	notifiers, err := BuildPluginIntegrations(map[string][]json.RawMessage{
+		name: {json.RawMessage(`{}`)},
+	}, tmpl, log.NewNopLogger())
+	require.Error(t, err)
+	require.Empty(t, notifiers)
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/encoding"
+)
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	req := &NotifyRequest{
+		Config: []byte(`{"a":1}`),
+		Alerts: []byte(`[{"b":2}]`),
+	}
+
+	codec := jsonCodec{}
+	data, err := codec.Marshal(req)
+	require.NoError(t, err)
+
+	var got NotifyRequest
+	require.NoError(t, codec.Unmarshal(data, &got))
+	require.JSONEq(t, string(req.Config), string(got.Config))
+	require.JSONEq(t, string(req.Alerts), string(got.Alerts))
+}
+
+func TestJSONCodecRegisteredUnderContentSubtype(t *testing.T) {
+	c := encoding.GetCodec(codecName)
+	require.NotNil(t, c)
+	require.Equal(t, codecName, c.Name())
+}
+
+type fakePluginServer struct {
+	resp *NotifyResponse
+	err  error
+}
+
+func (s *fakePluginServer) Notify(ctx context.Context, req *NotifyRequest) (*NotifyResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.resp, nil
+}
+
+func TestServeAndDialPluginRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := dir + "/plugin.sock"
+
+	srv := &fakePluginServer{resp: &NotifyResponse{Retry: true, Error: "upstream failed"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- Serve(ctx, socketPath, srv) }()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", socketPath)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	client, err := DialPlugin(ctx, socketPath)
+	require.NoError(t, err)
+	defer client.Close()
+
+	resp, err := client.Notify(ctx, &NotifyRequest{Config: []byte(`{}`), Alerts: []byte(`[]`)})
+	require.NoError(t, err)
+	require.Equal(t, srv.resp, resp)
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
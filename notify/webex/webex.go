@@ -0,0 +1,111 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	commoncfg "github.com/prometheus/common/config"
+)
+
+// Notifier implements a Notifier for Cisco Webex Teams.
+type Notifier struct {
+	conf   *config.WebexConfig
+	tmpl   *template.Template
+	logger log.Logger
+	client *http.Client
+}
+
+// webexMessage is the payload posted to the Webex "create message" API.
+// https://developer.webex.com/docs/api/v1/messages/create-a-message
+type webexMessage struct {
+	RoomID   string `json:"roomId"`
+	Markdown string `json:"markdown"`
+}
+
+// New returns a new Webex notifier.
+func New(c *config.WebexConfig, t *template.Template, l log.Logger, httpOpts ...commoncfg.HTTPClientOption) (*Notifier, error) {
+	client, err := commoncfg.NewClientFromConfig(*c.HTTPConfig, "webex", httpOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifier{conf: c, tmpl: t, logger: l, client: client}, nil
+}
+
+// Notify implements the Notifier interface.
+func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	var (
+		tmplErr error
+		data    = notify.GetTemplateData(ctx, n.tmpl, alerts, n.logger)
+		tmpl    = notify.TmplText(n.tmpl, data, &tmplErr)
+	)
+
+	msg := &webexMessage{
+		RoomID:   tmpl(n.conf.RoomID),
+		Markdown: tmpl(n.conf.Message),
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("templating error: %s", tmplErr)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(msg); err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.conf.APIURL, &buf)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+string(n.conf.BotToken))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer notify.Drain(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	level.Debug(n.logger).Log("response", string(body))
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("unexpected status code %d from webex: %s", resp.StatusCode, body)
+
+	// Webex rate-limits (429) and transient server errors (5xx) are worth
+	// retrying; any other 4xx means the request itself is malformed.
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5 {
+		return true, err
+	}
+
+	return false, err
+}
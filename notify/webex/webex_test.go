@@ -0,0 +1,81 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	commoncfg "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotifier(t *testing.T, apiURL string) *Notifier {
+	t.Helper()
+
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://am")
+	require.NoError(t, err)
+
+	n, err := New(&config.WebexConfig{
+		HTTPConfig: &commoncfg.HTTPClientConfig{},
+		APIURL:     apiURL,
+		BotToken:   "test-token",
+		RoomID:     "room-1",
+		Message:    "hi",
+	}, tmpl, log.NewNopLogger())
+	require.NoError(t, err)
+	return n
+}
+
+func TestNotifyRetryClassification(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		status    int
+		wantRetry bool
+		wantErr   bool
+	}{
+		{"ok", http.StatusOK, false, false},
+		{"too many requests", http.StatusTooManyRequests, true, true},
+		{"server error", http.StatusInternalServerError, true, true},
+		{"bad gateway", http.StatusBadGateway, true, true},
+		{"bad request", http.StatusBadRequest, false, true},
+		{"unauthorized", http.StatusUnauthorized, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer srv.Close()
+
+			n := newTestNotifier(t, srv.URL)
+			retry, err := n.Notify(context.Background(), &types.Alert{})
+
+			require.Equal(t, tc.wantRetry, retry)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wecomrobot
+
+import (
+	"encoding/json"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+)
+
+func init() {
+	notify.Register(wecomRobotPlugin{})
+}
+
+// wecomRobotPlugin adapts New to the notify.Plugin interface, proving the
+// registry against a notifier that already ships in-tree before any
+// out-of-tree plugin depends on it.
+type wecomRobotPlugin struct{}
+
+func (wecomRobotPlugin) Name() string { return "wecomrobot" }
+
+func (wecomRobotPlugin) New(raw json.RawMessage, tmpl *template.Template, logger log.Logger) (notify.Notifier, error) {
+	var c config.WeComRobotConfig
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+
+	n, err := New(&c, tmpl, logger)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
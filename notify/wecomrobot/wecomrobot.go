@@ -16,11 +16,21 @@ package wecomrobot
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -29,8 +39,120 @@ import (
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	commoncfg "github.com/prometheus/common/config"
+	"golang.org/x/time/rate"
 )
 
+// wecomAPIErrTooManyRequests is the WeCom robot API's own rate limit
+// errcode, returned with an HTTP 200 rather than an HTTP 429.
+// https://developer.work.weixin.qq.com/document/path/91770
+const wecomAPIErrTooManyRequests = 45009
+
+// wecomRobotLimiters holds one rate.Limiter per webhook URL so that
+// receivers sharing the same bot cooperate on its ~20 messages/minute quota.
+var wecomRobotLimiters sync.Map // map[string]*rate.Limiter
+
+const (
+	defaultRateLimit      = 20
+	defaultRateLimitBurst = 20
+	rateLimitWindow       = time.Minute
+
+	// defaultMaxMessageSize is WeCom's own limit on a "text" message's
+	// content, in bytes. https://developer.work.weixin.qq.com/document/path/91770
+	defaultMaxMessageSize = 2048
+)
+
+func limiterFor(webhookURL string, limit, burst int) *rate.Limiter {
+	if limit <= 0 {
+		limit = defaultRateLimit
+	}
+	if burst <= 0 {
+		burst = defaultRateLimitBurst
+	}
+	if v, ok := wecomRobotLimiters.Load(webhookURL); ok {
+		return v.(*rate.Limiter)
+	}
+	l := rate.NewLimiter(rate.Limit(float64(limit)/rateLimitWindow.Seconds()), burst)
+	actual, _ := wecomRobotLimiters.LoadOrStore(webhookURL, l)
+	return actual.(*rate.Limiter)
+}
+
+// waitRetryAfter sleeps for the duration named by resp's Retry-After header,
+// capped at ctx's deadline, if any.
+func waitRetryAfter(ctx context.Context, resp *http.Response) {
+	d := parseRetryAfter(resp.Header.Get("Retry-After"))
+	if d <= 0 {
+		return
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < d {
+			d = remaining
+		}
+	}
+	if d <= 0 {
+		return
+	}
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// mediaCacheTTL mirrors WeCom's own retention for uploaded media: a
+// media_id is only valid for 3 days after upload.
+const mediaCacheTTL = 3 * 24 * time.Hour
+
+type mediaCacheEntry struct {
+	mediaID   string
+	expiresAt time.Time
+}
+
+// wecomMediaCache caches media_id values by the sha256 of their content so
+// that repeatedly notifying about the same attachment (e.g. a static graph
+// URL) doesn't re-upload it on every alert.
+var wecomMediaCache sync.Map // map[string]mediaCacheEntry
+
+func cachedMediaID(contentHash string) (string, bool) {
+	v, ok := wecomMediaCache.Load(contentHash)
+	if !ok {
+		return "", false
+	}
+	entry := v.(mediaCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		wecomMediaCache.Delete(contentHash)
+		return "", false
+	}
+	return entry.mediaID, true
+}
+
+func cacheMediaID(contentHash, mediaID string) {
+	wecomMediaCache.Store(contentHash, mediaCacheEntry{mediaID: mediaID, expiresAt: time.Now().Add(mediaCacheTTL)})
+}
+
+// uploadMediaURL derives the WeCom media upload endpoint from the webhook
+// URL, reusing its "key" query parameter to identify the bot.
+func uploadMediaURL(webhookURL *url.URL, mediaType string) string {
+	u := *webhookURL
+	u.Path = "/cgi-bin/webhook/upload_media"
+	u.RawQuery = url.Values{
+		"key":  {webhookURL.Query().Get("key")},
+		"type": {mediaType},
+	}.Encode()
+	return u.String()
+}
+
 // Notifier implements a Notifier for generic wecomrobot.
 type Notifier struct {
 	conf   *config.WeComRobotConfig
@@ -46,6 +168,13 @@ type weComRobotResponse struct {
 
 // New returns a new Wechat notifier.
 func New(c *config.WeComRobotConfig, t *template.Template, l log.Logger, httpOpts ...commoncfg.HTTPClientOption) (*Notifier, error) {
+	// c may have been assembled from raw JSON (e.g. by wecomRobotPlugin.New)
+	// rather than unmarshaled from YAML, so UnmarshalYAML's validation may
+	// never have run. Validate it here too.
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
 	client, err := commoncfg.NewClientFromConfig(*c.HTTPConfig, "wecomrobot", httpOpts...)
 	if err != nil {
 		return nil, err
@@ -54,13 +183,64 @@ func New(c *config.WeComRobotConfig, t *template.Template, l log.Logger, httpOpt
 	return &Notifier{conf: c, tmpl: t, logger: l, client: client}, nil
 }
 
+// Mark is the WeCom "text" or "markdown" message body.
 type Mark struct {
-	Content string `json:"content"`
+	Content             string   `json:"content"`
+	MentionedList       []string `json:"mentioned_list,omitempty"`
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"`
+}
+
+// WeComRobotArticle is a single article rendered in a "news" message.
+type WeComRobotArticle struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	PicURL      string `json:"picurl,omitempty"`
+}
+
+type weComRobotNews struct {
+	Articles []WeComRobotArticle `json:"articles"`
+}
+
+type weComRobotImage struct {
+	Base64 string `json:"base64"`
+	MD5    string `json:"md5"`
+}
+
+type weComRobotFile struct {
+	MediaID string `json:"media_id"`
+}
+
+// WeComRobotCardTemplate is the "text_notice" flavor of a template_card
+// message, the one carrying the title/description/link an alert needs.
+type WeComRobotCardTemplate struct {
+	MainTitle   string `json:"main_title"`
+	Description string `json:"sub_title_text,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+type weComRobotCard struct {
+	CardType  string `json:"card_type"`
+	MainTitle struct {
+		Title string `json:"title"`
+		Desc  string `json:"desc,omitempty"`
+	} `json:"main_title"`
+	CardAction struct {
+		Type int    `json:"type"`
+		URL  string `json:"url,omitempty"`
+	} `json:"card_action"`
 }
 
+// WeComRobotMessage is the top-level payload posted to the WeCom group
+// robot webhook. Only the field matching Msgtype is populated.
 type WeComRobotMessage struct {
-	Msgtype string `json:"msgtype"`
-	Text    Mark   `json:"text"`
+	Msgtype      string           `json:"msgtype"`
+	Text         *Mark            `json:"text,omitempty"`
+	Markdown     *Mark            `json:"markdown,omitempty"`
+	News         *weComRobotNews  `json:"news,omitempty"`
+	Image        *weComRobotImage `json:"image,omitempty"`
+	File         *weComRobotFile  `json:"file,omitempty"`
+	TemplateCard *weComRobotCard  `json:"template_card,omitempty"`
 }
 
 // Notify implements the Notifier interface.
@@ -71,32 +251,56 @@ func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, er
 		tmpl    = notify.TmplText(n.tmpl, data, &tmplErr)
 	)
 
-	message := tmpl(n.conf.Message)
+	msg, err := n.buildMessage(tmpl)
+	if err != nil {
+		return false, err
+	}
 	if tmplErr != nil {
 		return false, fmt.Errorf("templating error: %s", tmplErr)
 	}
 
-	content, truncated := notify.TruncateInBytes(message, n.conf.MaxMessageSize)
-	if truncated {
-		level.Debug(n.logger).Log("msg", "message truncated due to exceeding maximum allowed length by wecom robot", "truncated_message", content)
+	if retry, err := n.send(ctx, msg); err != nil {
+		return retry, err
+	}
+
+	if n.conf.AttachmentURL == "" {
+		return false, nil
 	}
 
-	msg := WeComRobotMessage{
-		Msgtype: "text",
-		Text:    Mark{Content: content},
+	attachment, err := n.buildAttachmentMessage(ctx, tmpl)
+	if err != nil {
+		level.Warn(n.logger).Log("msg", "failed to prepare wecomrobot attachment", "err", err)
+		return false, nil
 	}
 
+	return n.send(ctx, attachment)
+}
+
+// send posts msg to the webhook, applying the receiver's rate limit and
+// classifying the response the same way for both the primary and any
+// chained attachment message.
+func (n *Notifier) send(ctx context.Context, msg *WeComRobotMessage) (bool, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(msg); err != nil {
 		return false, err
 	}
 
-	resp, err := notify.PostJSON(ctx, n.client, n.conf.WebhookURL.String(), &buf)
+	webhookURL := n.conf.WebhookURL.String()
+	if err := limiterFor(webhookURL, n.conf.RateLimit, n.conf.RateLimitBurst).Wait(ctx); err != nil {
+		return true, err
+	}
+
+	resp, err := notify.PostJSON(ctx, n.client, webhookURL, &buf)
 	if err != nil {
 		return true, err
 	}
 	defer notify.Drain(resp)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		waitRetryAfter(ctx, resp)
+		return true, fmt.Errorf("wecom robot rate limit exceeded (HTTP %d)", resp.StatusCode)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return true, err
@@ -113,5 +317,204 @@ func (n *Notifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, er
 		return false, nil
 	}
 
+	if wecomrobotResp.Code == wecomAPIErrTooManyRequests {
+		waitRetryAfter(ctx, resp)
+		return true, errors.New(wecomrobotResp.Error)
+	}
+
 	return false, errors.New(wecomrobotResp.Error)
 }
+
+// buildMessage renders n.conf into the WeComRobotMessage payload matching
+// n.conf.MsgType, defaulting to a plain "text" message for backward
+// compatibility.
+func (n *Notifier) buildMessage(tmpl func(string) string) (*WeComRobotMessage, error) {
+	msgType := n.conf.MsgType
+	if msgType == "" {
+		msgType = "text"
+	}
+
+	mentionedList := templateMentions(tmpl, n.conf.MentionedList)
+	mentionedMobileList := templateMentions(tmpl, n.conf.MentionedMobileList)
+
+	switch msgType {
+	case "text":
+		maxMessageSize := n.conf.MaxMessageSize
+		if maxMessageSize <= 0 {
+			maxMessageSize = defaultMaxMessageSize
+		}
+		content, truncated := notify.TruncateInBytes(tmpl(n.conf.Message), maxMessageSize)
+		if truncated {
+			level.Debug(n.logger).Log("msg", "message truncated due to exceeding maximum allowed length by wecom robot", "truncated_message", content)
+		}
+		return &WeComRobotMessage{Msgtype: msgType, Text: &Mark{
+			Content:             content,
+			MentionedList:       mentionedList,
+			MentionedMobileList: mentionedMobileList,
+		}}, nil
+
+	case "markdown":
+		// The markdown size limit is 4096 bytes, but unlike "text" the
+		// message is structured, so truncating it by raw byte count would
+		// risk producing invalid markdown. Leave it untruncated.
+		return &WeComRobotMessage{Msgtype: msgType, Markdown: &Mark{
+			Content:             tmpl(n.conf.Message),
+			MentionedList:       mentionedList,
+			MentionedMobileList: mentionedMobileList,
+		}}, nil
+
+	case "news":
+		articles := make([]WeComRobotArticle, 0, len(n.conf.Articles))
+		for _, a := range n.conf.Articles {
+			articles = append(articles, WeComRobotArticle{
+				Title:       tmpl(a.Title),
+				Description: tmpl(a.Description),
+				URL:         tmpl(a.URL),
+				PicURL:      tmpl(a.PicURL),
+			})
+		}
+		return &WeComRobotMessage{Msgtype: msgType, News: &weComRobotNews{Articles: articles}}, nil
+
+	case "image":
+		if n.conf.ImageBase64 == "" || n.conf.ImageMD5 == "" {
+			return nil, fmt.Errorf("wecomrobot msg_type \"image\" requires image_base64 and image_md5")
+		}
+		return &WeComRobotMessage{Msgtype: msgType, Image: &weComRobotImage{
+			Base64: n.conf.ImageBase64,
+			MD5:    n.conf.ImageMD5,
+		}}, nil
+
+	case "template_card":
+		if n.conf.CardTemplate == nil {
+			return nil, fmt.Errorf("wecomrobot msg_type \"template_card\" requires a card_template block")
+		}
+		card := &weComRobotCard{CardType: "text_notice"}
+		card.MainTitle.Title = tmpl(n.conf.CardTemplate.MainTitle)
+		card.MainTitle.Desc = tmpl(n.conf.CardTemplate.Description)
+		card.CardAction.URL = tmpl(n.conf.CardTemplate.URL)
+		if card.CardAction.URL != "" {
+			card.CardAction.Type = 1
+		}
+		return &WeComRobotMessage{Msgtype: msgType, TemplateCard: card}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported wecomrobot msgtype %q", msgType)
+	}
+}
+
+// templateMentions renders each entry of raw and drops any that template to
+// an empty string.
+func templateMentions(tmpl func(string) string, raw []string) []string {
+	mentions := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if m := tmpl(r); m != "" {
+			mentions = append(mentions, m)
+		}
+	}
+	return mentions
+}
+
+// buildAttachmentMessage downloads n.conf.AttachmentURL and wraps it as a
+// second WeComRobotMessage: an uploaded "file" referencing the resulting
+// media_id for AttachmentType "file", or an inline "image" otherwise.
+func (n *Notifier) buildAttachmentMessage(ctx context.Context, tmpl func(string) string) (*WeComRobotMessage, error) {
+	attachmentURL := tmpl(n.conf.AttachmentURL)
+
+	data, err := n.downloadAttachment(ctx, attachmentURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading wecomrobot attachment: %w", err)
+	}
+
+	if n.conf.AttachmentType == "file" {
+		mediaID, err := n.uploadFile(ctx, data, path.Base(attachmentURL))
+		if err != nil {
+			return nil, fmt.Errorf("uploading wecomrobot attachment: %w", err)
+		}
+		return &WeComRobotMessage{Msgtype: "file", File: &weComRobotFile{MediaID: mediaID}}, nil
+	}
+
+	sum := md5.Sum(data)
+	return &WeComRobotMessage{Msgtype: "image", Image: &weComRobotImage{
+		Base64: base64.StdEncoding.EncodeToString(data),
+		MD5:    hex.EncodeToString(sum[:]),
+	}}, nil
+}
+
+// downloadAttachment fetches attachmentURL over HTTP(S) and returns its raw
+// bytes. AttachmentURL is templated from alert annotations, so it is treated
+// as an untrusted URL rather than a local filesystem path: resolving it
+// against the local filesystem would let anyone able to push an alert read
+// arbitrary files off the host running Alertmanager.
+func (n *Notifier) downloadAttachment(ctx context.Context, attachmentURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachmentURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer notify.Drain(resp)
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected status code %d downloading %s", resp.StatusCode, attachmentURL)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// uploadFile uploads data to the WeCom media upload endpoint and returns
+// its media_id, reusing a cached media_id for content already uploaded
+// within its 3-day validity window.
+func (n *Notifier) uploadFile(ctx context.Context, data []byte, filename string) (string, error) {
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+	if mediaID, ok := cachedMediaID(contentHash); ok {
+		return mediaID, nil
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("media", filename)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadMediaURL(n.conf.WebhookURL.URL, "file"), &buf)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer notify.Drain(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var uploadResp struct {
+		weComRobotResponse
+		MediaID string `json:"media_id"`
+	}
+	if err := json.Unmarshal(body, &uploadResp); err != nil {
+		return "", err
+	}
+	if uploadResp.Code != 0 {
+		return "", errors.New(uploadResp.Error)
+	}
+
+	cacheMediaID(contentHash, uploadResp.MediaID)
+	return uploadResp.MediaID, nil
+}
@@ -0,0 +1,301 @@
+// Copyright 2023 Prometheus Team
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wecomrobot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	commoncfg "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotifier(t *testing.T, webhookURL string, c *config.WeComRobotConfig) *Notifier {
+	t.Helper()
+
+	c.HTTPConfig = &commoncfg.HTTPClientConfig{}
+	u, err := url.Parse(webhookURL)
+	require.NoError(t, err)
+	c.WebhookURL = config.URL{URL: u}
+
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://am")
+	require.NoError(t, err)
+
+	n, err := New(c, tmpl, log.NewNopLogger())
+	require.NoError(t, err)
+	return n
+}
+
+func TestLimiterForReusesBucketPerURL(t *testing.T) {
+	a := limiterFor("https://example.com/a", 0, 0)
+	b := limiterFor("https://example.com/a", 0, 0)
+	c := limiterFor("https://example.com/b", 0, 0)
+
+	require.Same(t, a, b)
+	require.NotSame(t, a, c)
+}
+
+func TestLimiterForDefaultsAndOverrides(t *testing.T) {
+	limiterFor("https://example.com/defaults", 0, 0)
+	l := limiterFor("https://example.com/defaults", 0, 0)
+	require.InDelta(t, float64(defaultRateLimit)/rateLimitWindow.Seconds(), float64(l.Limit()), 1e-9)
+	require.Equal(t, defaultRateLimitBurst, l.Burst())
+
+	l2 := limiterFor("https://example.com/custom", 60, 5)
+	require.InDelta(t, 1.0, float64(l2.Limit()), 1e-9)
+	require.Equal(t, 5, l2.Burst())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"invalid", "soon", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, parseRetryAfter(tc.in))
+		})
+	}
+}
+
+func TestNotifyRetriesOnHTTP429(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, srv.URL+"?key=test", &config.WeComRobotConfig{
+		MsgType: "text",
+		Message: "hi",
+	})
+
+	ctx := context.Background()
+	retry, err := n.Notify(ctx, &types.Alert{})
+	require.Error(t, err)
+	require.True(t, retry)
+}
+
+func TestNotifyRetriesOnRateLimitErrcode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":45009,"errmsg":"api freq out of limit"}`))
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, srv.URL+"?key=test", &config.WeComRobotConfig{
+		MsgType: "text",
+		Message: "hi",
+	})
+
+	ctx := context.Background()
+	retry, err := n.Notify(ctx, &types.Alert{})
+	require.Error(t, err)
+	require.True(t, retry)
+}
+
+func TestNotifySucceedsOnErrcodeZero(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, srv.URL+"?key=test", &config.WeComRobotConfig{
+		MsgType: "text",
+		Message: "hi",
+	})
+
+	ctx := context.Background()
+	retry, err := n.Notify(ctx, &types.Alert{})
+	require.NoError(t, err)
+	require.False(t, retry)
+}
+
+func TestCachedMediaIDExpiry(t *testing.T) {
+	wecomMediaCache.Store("deadbeef", mediaCacheEntry{mediaID: "media-1", expiresAt: time.Now().Add(-time.Second)})
+	_, ok := cachedMediaID("deadbeef")
+	require.False(t, ok)
+
+	wecomMediaCache.Store("cafebabe", mediaCacheEntry{mediaID: "media-2", expiresAt: time.Now().Add(time.Hour)})
+	id, ok := cachedMediaID("cafebabe")
+	require.True(t, ok)
+	require.Equal(t, "media-2", id)
+}
+
+func TestUploadFileCachesMediaID(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok","media_id":"media-123"}`))
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, srv.URL+"?key=test", &config.WeComRobotConfig{MsgType: "text", Message: "hi"})
+
+	data := []byte("hello world")
+	id1, err := n.uploadFile(context.Background(), data, "note.txt")
+	require.NoError(t, err)
+	require.Equal(t, "media-123", id1)
+
+	id2, err := n.uploadFile(context.Background(), data, "note.txt")
+	require.NoError(t, err)
+	require.Equal(t, "media-123", id2)
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func identity(s string) string { return s }
+
+func TestBuildMessageText(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{
+		MsgType:       "text",
+		Message:       "hi",
+		MentionedList: []string{"@all", ""},
+	})
+
+	msg, err := n.buildMessage(identity)
+	require.NoError(t, err)
+	require.Equal(t, "text", msg.Msgtype)
+	require.Equal(t, "hi", msg.Text.Content)
+	require.Equal(t, []string{"@all"}, msg.Text.MentionedList)
+}
+
+func TestBuildMessageMarkdown(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{
+		MsgType: "markdown",
+		Message: "**hi**",
+	})
+
+	msg, err := n.buildMessage(identity)
+	require.NoError(t, err)
+	require.Equal(t, "markdown", msg.Msgtype)
+	require.Equal(t, "**hi**", msg.Markdown.Content)
+}
+
+func TestBuildMessageNews(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{
+		MsgType: "news",
+		Articles: []config.WeComRobotArticle{
+			{Title: "title", Description: "desc", URL: "https://example.com", PicURL: "https://example.com/pic.png"},
+		},
+	})
+
+	msg, err := n.buildMessage(identity)
+	require.NoError(t, err)
+	require.Equal(t, "news", msg.Msgtype)
+	require.Len(t, msg.News.Articles, 1)
+	require.Equal(t, "title", msg.News.Articles[0].Title)
+}
+
+func TestBuildMessageImage(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{
+		MsgType:     "image",
+		ImageBase64: "YmFzZTY0",
+		ImageMD5:    "deadbeef",
+	})
+
+	msg, err := n.buildMessage(identity)
+	require.NoError(t, err)
+	require.Equal(t, "image", msg.Msgtype)
+	require.Equal(t, "YmFzZTY0", msg.Image.Base64)
+}
+
+func TestBuildMessageImageMissingFieldsErrors(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{MsgType: "text"})
+	// buildMessage is only reachable at all with a valid config, so set
+	// MsgType directly to exercise its own missing-fields check.
+	n.conf.MsgType = "image"
+	n.conf.ImageBase64 = ""
+	n.conf.ImageMD5 = ""
+
+	_, err := n.buildMessage(identity)
+	require.Error(t, err)
+}
+
+func TestBuildMessageTemplateCard(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{
+		MsgType: "template_card",
+		CardTemplate: &config.WeComRobotCardTemplate{
+			MainTitle:   "title",
+			Description: "desc",
+			URL:         "https://example.com",
+		},
+	})
+
+	msg, err := n.buildMessage(identity)
+	require.NoError(t, err)
+	require.Equal(t, "template_card", msg.Msgtype)
+	require.Equal(t, "title", msg.TemplateCard.MainTitle.Title)
+	require.Equal(t, 1, msg.TemplateCard.CardAction.Type)
+}
+
+func TestBuildMessageTemplateCardMissingBlockErrors(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{MsgType: "text"})
+	// buildMessage is only reachable at all with a valid config, so set
+	// MsgType directly to exercise its own nil-CardTemplate check.
+	n.conf.MsgType = "template_card"
+	n.conf.CardTemplate = nil
+
+	_, err := n.buildMessage(identity)
+	require.Error(t, err)
+}
+
+func TestTemplateMentions(t *testing.T) {
+	got := templateMentions(identity, []string{"@alice", "", "@bob"})
+	require.Equal(t, []string{"@alice", "@bob"}, got)
+
+	require.Empty(t, templateMentions(identity, nil))
+}
+
+func TestDownloadAttachmentRejectsLocalPath(t *testing.T) {
+	n := newTestNotifier(t, "https://example.com/webhook?key=test", &config.WeComRobotConfig{MsgType: "text"})
+
+	// AttachmentURL is templated from alert annotations, so a bare
+	// filesystem path must not be read off disk: it has to fail as an
+	// invalid HTTP request rather than leak local file contents.
+	_, err := n.downloadAttachment(context.Background(), "/etc/passwd")
+	require.Error(t, err)
+}
+
+func TestNotifyAttachmentFailureDoesNotFailNotify(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	n := newTestNotifier(t, srv.URL+"?key=test", &config.WeComRobotConfig{
+		MsgType:        "text",
+		Message:        "hi",
+		AttachmentURL:  "http://127.0.0.1:0/nope",
+		AttachmentType: "image",
+	})
+
+	retry, err := n.Notify(context.Background(), &types.Alert{})
+	require.NoError(t, err)
+	require.False(t, retry)
+}